@@ -12,8 +12,11 @@ import (
 	"regexp"
 
 	"github.com/xakep666/licensevalidator/pkg/athens"
+	"github.com/xakep666/licensevalidator/pkg/auth"
 	"github.com/xakep666/licensevalidator/pkg/cache"
+	"github.com/xakep666/licensevalidator/pkg/gitea"
 	"github.com/xakep666/licensevalidator/pkg/github"
+	"github.com/xakep666/licensevalidator/pkg/gitlab"
 	"github.com/xakep666/licensevalidator/pkg/golang"
 	"github.com/xakep666/licensevalidator/pkg/gopkg"
 	"github.com/xakep666/licensevalidator/pkg/goproxy"
@@ -24,6 +27,7 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	gh "github.com/google/go-github/v18/github"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 )
@@ -33,6 +37,13 @@ type App struct {
 	server *http.Server
 }
 
+// namedResolver pairs a license resolver with the name it should report
+// under in resolver latency metrics.
+type namedResolver struct {
+	name     string
+	resolver validation.LicenseResolver
+}
+
 func NewApp(cfg Config) (*App, error) {
 	var logger *zap.Logger
 	if cfg.Debug {
@@ -48,23 +59,58 @@ func NewApp(cfg Config) (*App, error) {
 		return nil, fmt.Errorf("translator init failed: %w", err)
 	}
 
+	var metrics *observ.Metrics
+	if cfg.Server.EnableMetrics {
+		metrics = observ.NewMetrics()
+	}
+
+	named := []namedResolver{
+		{"github", githubClient(logger, &cfg)},
+	}
+
+	if cfg.GitLab != nil {
+		named = append(named, namedResolver{"gitlab", gitlabClient(logger, &cfg)})
+	}
+
+	if cfg.Gitea != nil {
+		named = append(named, namedResolver{"gitea", giteaClient(logger, &cfg)})
+	}
+
+	named = append(named, namedResolver{"goproxy", goproxyClient(logger, &cfg)})
+
+	resolvers := make([]validation.LicenseResolver, 0, len(named))
+	for _, n := range named {
+		r := n.resolver
+		if metrics != nil {
+			r = metrics.WrapResolver(n.name, r)
+		}
+		resolvers = append(resolvers, r)
+	}
+
 	c, err := setupCache(&cfg, cache.Direct{
 		LicenseResolver: &validation.ChainedLicenseResolver{
-			LicenseResolvers: []validation.LicenseResolver{
-				githubClient(logger, &cfg),
-				goproxyClient(logger, &cfg),
-			},
+			LicenseResolvers: resolvers,
 		},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("setup cache failed: %w", err)
 	}
 
-	validator, err := validator(logger, &cfg, translator, c)
+	var resolverCache validation.LicenseResolver = c
+	if metrics != nil {
+		resolverCache = cache.NewInstrumented(metrics.Registry, c)
+	}
+
+	notifyingValidator, err := validator(logger, &cfg, translator, resolverCache)
 	if err != nil {
 		return nil, fmt.Errorf("validator init failed: %w", err)
 	}
 
+	var admissionValidator validation.Validator = notifyingValidator
+	if metrics != nil {
+		admissionValidator = validation.NewInstrumented(metrics.Registry, admissionValidator)
+	}
+
 	logger.Info("Trying to resolve goproxy addresses", zap.String("goproxy", string(cfg.GoProxy.BaseURL)))
 
 	goproxyAddrs, err := goproxyAddrs(&cfg)
@@ -74,18 +120,27 @@ func NewApp(cfg Config) (*App, error) {
 
 	logger.Info("Found forbidden admission request sources", zap.Strings("sources", goproxyAddrs))
 
+	admissionAuth, pprofAuth, err := setupAuth(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("auth setup failed: %w", err)
+	}
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("/athens/admission", athens.AdmissionHandler(
-		&athens.InternalValidator{Validator: validator},
+	mux.Handle("/athens/admission", admissionAuth(athens.AdmissionHandler(
+		&athens.InternalValidator{Validator: admissionValidator},
 		goproxyAddrs...,
-	))
-	addPprofHandlers(&cfg, mux)
+	)))
+	addPprofHandlers(&cfg, mux, pprofAuth)
+
+	if metrics != nil {
+		mux.Handle("/metrics", metrics.MetricsHandler())
+	}
 
 	return &App{
 		logger: logger,
 		server: &http.Server{
 			Addr:    cfg.Server.ListenAddr,
-			Handler: observ.Middleware(logger)(mux),
+			Handler: observ.Middleware(logger, metrics, cfg.Server.SlowRequestThreshold)(mux),
 			ErrorLog: func() *log.Logger {
 				l, _ := zap.NewStdLogAt(logger, zap.ErrorLevel)
 				return l
@@ -116,8 +171,23 @@ func setupCache(cfg *Config, cacher cache.Cacher) (cache.Cacher, error) {
 
 	switch cfg.Cache.Type {
 	case CacheTypeMemory:
-		return &cache.MemoryCache{
-			Backed: cacher,
+		return &cache.Expiring{
+			Store:         &cache.MemoryStore{},
+			Backed:        cacher,
+			TTL:           cfg.Cache.TTL,
+			NegativeTTL:   cfg.Cache.NegativeTTL,
+			RefreshWindow: cfg.Cache.RefreshWindow,
+		}, nil
+	case CacheTypeRedis:
+		return &cache.Expiring{
+			Store: &cache.RedisCache{
+				Client:  redis.NewClient(&redis.Options{Addr: cfg.Cache.Redis.Addr}),
+				LRUSize: cfg.Cache.Redis.LRUSize,
+			},
+			Backed:        cacher,
+			TTL:           cfg.Cache.TTL,
+			NegativeTTL:   cfg.Cache.NegativeTTL,
+			RefreshWindow: cfg.Cache.RefreshWindow,
 		}, nil
 	default:
 		return nil, fmt.Errorf("invalid cache type: %s", cfg.Cache.Type)
@@ -139,6 +209,45 @@ func githubClient(log *zap.Logger, cfg *Config) *github.Client {
 	})
 }
 
+func gitlabClient(log *zap.Logger, cfg *Config) *gitlab.Client {
+	hosts := cfg.GitLab.HostMappings
+	if cfg.GitLab.BaseURL == "" || isGitLabCom(cfg.GitLab.BaseURL) {
+		// Only claim gitlab.com-hosted modules when we're actually talking to
+		// gitlab.com; for a private instance its operator owns the full list
+		// of hosts via HostMappings.
+		hosts = append([]string{"gitlab.com"}, hosts...)
+	}
+
+	return gitlab.NewClient(log, gitlab.ClientParams{
+		HTTPClient:                  &http.Client{},
+		BaseURL:                     cfg.GitLab.BaseURL,
+		AccessToken:                 string(cfg.GitLab.AccessToken),
+		Hosts:                       hosts,
+		FallbackConfidenceThreshold: cfg.Validation.ConfidenceThreshold,
+	})
+}
+
+// isGitLabCom reports whether baseURL points at the public gitlab.com
+// instance, as opposed to a self-hosted one.
+func isGitLabCom(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+
+	return u.Hostname() == "gitlab.com"
+}
+
+func giteaClient(log *zap.Logger, cfg *Config) *gitea.Client {
+	return gitea.NewClient(log, gitea.ClientParams{
+		HTTPClient:                  &http.Client{},
+		BaseURL:                     cfg.Gitea.BaseURL,
+		AccessToken:                 string(cfg.Gitea.AccessToken),
+		Hosts:                       cfg.Gitea.HostMappings,
+		FallbackConfidenceThreshold: cfg.Validation.ConfidenceThreshold,
+	})
+}
+
 func goproxyClient(log *zap.Logger, cfg *Config) *goproxy.Client {
 	if cfg.GoProxy.BaseURL == "" {
 		cfg.GoProxy.BaseURL = "https://proxy.golang.org"
@@ -298,12 +407,70 @@ func parseLicenses(ls []License) ([]validation.License, error) {
 	return ret, nil
 }
 
-func addPprofHandlers(cfg *Config, mux *http.ServeMux) {
+func addPprofHandlers(cfg *Config, mux *http.ServeMux, authMW func(http.Handler) http.Handler) {
 	if cfg.Server.EnablePprof {
-		mux.HandleFunc("/pprof/", pprof.Index)
-		mux.HandleFunc("/pprof/cmdline", pprof.Cmdline)
-		mux.HandleFunc("/pprof/profile", pprof.Profile)
-		mux.HandleFunc("/pprof/symbol", pprof.Symbol)
-		mux.HandleFunc("/pprof/trace", pprof.Trace)
+		mux.Handle("/pprof/", authMW(http.HandlerFunc(pprof.Index)))
+		mux.Handle("/pprof/cmdline", authMW(http.HandlerFunc(pprof.Cmdline)))
+		mux.Handle("/pprof/profile", authMW(http.HandlerFunc(pprof.Profile)))
+		mux.Handle("/pprof/symbol", authMW(http.HandlerFunc(pprof.Symbol)))
+		mux.Handle("/pprof/trace", authMW(http.HandlerFunc(pprof.Trace)))
+	}
+}
+
+// setupAuth builds the middleware protecting the admission and pprof
+// endpoints from cfg.Server.Auth. Both default to a no-op passthrough so
+// existing deployments without an Auth section configured are unaffected.
+// When OIDC is configured, pprof requires a verified OIDC token with a
+// "role=admin" claim, full stop -- a static bearer token never satisfies it,
+// even if Tokens is also configured, since it carries no role information.
+// The admission endpoint accepts either credential when both are configured.
+func setupAuth(cfg *Config) (admissionMW, pprofMW func(http.Handler) http.Handler, err error) {
+	passthrough := func(next http.Handler) http.Handler { return next }
+	admissionMW, pprofMW = passthrough, passthrough
+
+	if cfg.Server.Auth == nil {
+		return admissionMW, pprofMW, nil
 	}
+
+	var bearerMW func(http.Handler) http.Handler
+	if len(cfg.Server.Auth.Tokens) > 0 {
+		tokens := make([]string, len(cfg.Server.Auth.Tokens))
+		for i, t := range cfg.Server.Auth.Tokens {
+			tokens[i] = string(t)
+		}
+
+		bearerMW = auth.Bearer(tokens)
+	}
+
+	var admissionOIDCMW, pprofOIDCMW func(http.Handler) http.Handler
+	if cfg.Server.Auth.OIDC != nil {
+		verifier, err := auth.NewOIDCVerifier(context.Background(),
+			cfg.Server.Auth.OIDC.IssuerURL, cfg.Server.Auth.OIDC.Audience)
+		if err != nil {
+			return nil, nil, fmt.Errorf("oidc verifier init failed: %w", err)
+		}
+
+		admissionOIDCMW = auth.RequireOIDC(verifier, nil)
+		pprofOIDCMW = auth.RequireOIDC(verifier, map[string]string{"role": "admin"})
+	}
+
+	switch {
+	case bearerMW != nil && admissionOIDCMW != nil:
+		admissionMW = auth.Any(bearerMW, admissionOIDCMW)
+	case bearerMW != nil:
+		admissionMW = bearerMW
+	case admissionOIDCMW != nil:
+		admissionMW = admissionOIDCMW
+	}
+
+	switch {
+	// pprof never falls back to a bare bearer token once OIDC is configured:
+	// a static token can't carry the role=admin claim OIDC enforces here.
+	case pprofOIDCMW != nil:
+		pprofMW = pprofOIDCMW
+	case bearerMW != nil:
+		pprofMW = bearerMW
+	}
+
+	return admissionMW, pprofMW, nil
 }
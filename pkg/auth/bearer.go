@@ -0,0 +1,39 @@
+// Package auth provides pluggable HTTP authentication middleware (static
+// bearer tokens and OIDC) for protecting endpoints such as the Athens
+// admission webhook and the pprof debug routes.
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Bearer returns a middleware that only admits requests whose
+// "Authorization: Bearer <token>" header matches one of tokens. Comparison
+// is constant-time so a valid token can't be recovered by timing failed
+// attempts.
+func Bearer(tokens []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			presented := strings.TrimPrefix(header, "Bearer ")
+			if !strings.HasPrefix(header, "Bearer ") || presented == "" || !matchesAny(presented, tokens) {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func matchesAny(presented string, tokens []string) bool {
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// probeWriter records a response without touching a real ResponseWriter, so
+// Any can try a middleware speculatively and discard the attempt if it
+// didn't admit the request.
+type probeWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newProbeWriter() *probeWriter {
+	return &probeWriter{header: make(http.Header)}
+}
+
+func (p *probeWriter) Header() http.Header { return p.header }
+
+func (p *probeWriter) Write(b []byte) (int, error) {
+	if p.statusCode == 0 {
+		p.statusCode = http.StatusOK
+	}
+
+	return p.body.Write(b)
+}
+
+func (p *probeWriter) WriteHeader(code int) { p.statusCode = code }
+
+// Any returns a middleware that admits a request if any of mws would admit
+// it, trying them in order. Use this when an endpoint accepts more than one
+// credential type, e.g. either a static bearer token or a valid OIDC token.
+//
+// If none of mws admit the request, the last one's rejection (status code
+// and body) is surfaced to the caller.
+func Any(mws ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var rejection *probeWriter
+
+			for _, mw := range mws {
+				admitted := false
+				probe := newProbeWriter()
+
+				mw(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+					admitted = true
+				})).ServeHTTP(probe, r)
+
+				if admitted {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				rejection = probe
+			}
+
+			for k, vs := range rejection.header {
+				w.Header()[k] = vs
+			}
+
+			code := rejection.statusCode
+			if code == 0 {
+				code = http.StatusOK
+			}
+			w.WriteHeader(code)
+			_, _ = w.Write(rejection.body.Bytes())
+		})
+	}
+}
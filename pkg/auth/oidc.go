@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// NewOIDCVerifier discovers issuerURL's OIDC configuration and returns a
+// verifier that checks ID token signatures against the provider's JWKS
+// (fetched lazily and cached internally by go-oidc) and enforces audience.
+func NewOIDCVerifier(ctx context.Context, issuerURL, audience string) (*oidc.IDTokenVerifier, error) {
+	provider, err := oidc.NewProvider(ctx, issuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("oidc provider discovery failed: %w", err)
+	}
+
+	return provider.Verifier(&oidc.Config{ClientID: audience}), nil
+}
+
+// RequireOIDC returns a middleware that verifies the bearer token against
+// verifier and, when requiredClaims is non-empty, rejects tokens whose
+// claims don't match every one of them: a string claim must equal the wanted
+// value, an array claim must contain it (see claimMatches).
+func RequireOIDC(verifier *oidc.IDTokenVerifier, requiredClaims map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if raw == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			idToken, err := verifier.Verify(r.Context(), raw)
+			if err != nil {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if len(requiredClaims) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var claims map[string]interface{}
+			if err := idToken.Claims(&claims); err != nil {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			for claim, want := range requiredClaims {
+				if !claimMatches(claims[claim], want) {
+					http.Error(w, "forbidden", http.StatusForbidden)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// claimMatches reports whether want is satisfied by a claim value, which may
+// be a single string or, for a role/group-list claim such as "roles", an
+// array of strings.
+func claimMatches(claim interface{}, want string) bool {
+	switch v := claim.(type) {
+	case string:
+		return v == want
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+)
+
+// backgroundRefreshTimeout bounds how long a stale-while-revalidate refresh
+// triggered outside the request path is allowed to run.
+const backgroundRefreshTimeout = 30 * time.Second
+
+const (
+	errKindNotFound    = "not_found"
+	errKindUnsupported = "unsupported_module"
+)
+
+// Expiring decorates a Store with TTL and negative-caching semantics: it
+// serves cached entries from Store and falls back to Backed on miss. A
+// successful resolution is cached for TTL, a failed one for the usually much
+// shorter NegativeTTL so a transient upstream failure doesn't get pinned. An
+// entry read within RefreshWindow of its expiry is still served, but a
+// refresh against Backed is kicked off in the background so the next request
+// finds a fresh value; at most one such refresh runs per key at a time.
+//
+// Because it only depends on the Store interface, Expiring works the same
+// whether Store is MemoryStore or RedisCache.
+type Expiring struct {
+	Store  Store
+	Backed validation.LicenseResolver
+
+	TTL           time.Duration
+	NegativeTTL   time.Duration
+	RefreshWindow time.Duration
+
+	refreshing sync.Map // key string -> struct{}, tracks in-flight background refreshes
+}
+
+// Resolve implements validation.LicenseResolver.
+func (e *Expiring) Resolve(ctx context.Context, module, version string) (validation.License, error) {
+	key := module + "@" + version
+
+	entry, ok, err := e.Store.Get(ctx, key)
+	if err != nil {
+		// Store unavailable: don't fail the request, just bypass the cache.
+		return e.fetch(ctx, key, module, version)
+	}
+
+	if !ok {
+		return e.fetch(ctx, key, module, version)
+	}
+
+	markHit(ctx)
+
+	if time.Until(entry.ExpiresAt) < e.RefreshWindow {
+		e.triggerBackgroundRefresh(key, module, version)
+	}
+
+	if entry.Err != "" || entry.ErrKind != "" {
+		return validation.License{}, decodeErr(entry.ErrKind, entry.Err)
+	}
+
+	return entry.License, nil
+}
+
+// triggerBackgroundRefresh starts a background refresh for key unless one is
+// already in flight, so a hot key under load doesn't spawn a refresh per
+// request.
+func (e *Expiring) triggerBackgroundRefresh(key, module, version string) {
+	if _, inFlight := e.refreshing.LoadOrStore(key, struct{}{}); inFlight {
+		return
+	}
+
+	go e.refreshInBackground(key, module, version)
+}
+
+func (e *Expiring) fetch(ctx context.Context, key, module, version string) (validation.License, error) {
+	markMiss(ctx)
+
+	license, err := e.Backed.Resolve(ctx, module, version)
+
+	ttl := e.TTL
+	entry := StoredEntry{License: license}
+	if err != nil {
+		ttl = e.NegativeTTL
+		entry.ErrKind, entry.Err = encodeErr(err)
+	}
+	entry.ExpiresAt = time.Now().Add(ttl)
+
+	// A failed cache write shouldn't fail a successful resolution, the next
+	// request will simply miss the cache again.
+	_ = e.Store.Set(ctx, key, entry, ttl)
+
+	return license, err
+}
+
+func (e *Expiring) refreshInBackground(key, module, version string) {
+	defer e.refreshing.Delete(key)
+
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundRefreshTimeout)
+	defer cancel()
+
+	_, _ = e.fetch(ctx, key, module, version)
+}
+
+// encodeErr splits err into a StoredEntry's ErrKind/Err pair, recording a
+// discriminator for the sentinel errors resolvers use so decodeErr can
+// reconstruct them with their identity intact.
+func encodeErr(err error) (kind, msg string) {
+	switch {
+	case errors.Is(err, validation.ErrLicenseNotFound):
+		return errKindNotFound, err.Error()
+	case errors.Is(err, validation.ErrUnsupportedModule):
+		return errKindUnsupported, err.Error()
+	default:
+		return "", err.Error()
+	}
+}
+
+// decodeErr reverses encodeErr, returning the original sentinel error for a
+// known kind so downstream errors.Is checks (chain fallthrough, unknown-
+// license handling) see the same error whether the result came from cache or
+// a fresh resolution. An unrecognized or empty kind (including entries
+// cached before ErrKind existed) falls back to an error carrying just msg.
+func decodeErr(kind, msg string) error {
+	switch kind {
+	case errKindNotFound:
+		return validation.ErrLicenseNotFound
+	case errKindUnsupported:
+		return validation.ErrUnsupportedModule
+	default:
+		return errors.New(msg)
+	}
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type missTrackingKey struct{}
+
+// outcome is the resolution outcome reported by Backed through markHit or
+// markMiss. It defaults to outcomeUnknown, which Instrumented treats as a
+// miss: a Cacher that never reports either (e.g. a plain MemoryCache with no
+// Expiring in front of it) can't be trusted to have actually served from
+// cache.
+type outcome int
+
+const (
+	outcomeUnknown outcome = iota
+	outcomeHit
+	outcomeMiss
+)
+
+// markHit records that the current Resolve call was served from cache
+// without reaching the backing resolver. Expiring calls this whenever it
+// serves a cached entry directly.
+func markHit(ctx context.Context) {
+	if o, ok := ctx.Value(missTrackingKey{}).(*outcome); ok {
+		*o = outcomeHit
+	}
+}
+
+// markMiss records that the current Resolve call actually reached the
+// backing resolver instead of being served from cache. Expiring calls this
+// whenever it falls through to Backed, so Instrumented can report accurate
+// hit/miss counters regardless of which Store is behind it.
+func markMiss(ctx context.Context) {
+	if o, ok := ctx.Value(missTrackingKey{}).(*outcome); ok {
+		*o = outcomeMiss
+	}
+}
+
+func withMissTracking(ctx context.Context) (context.Context, *outcome) {
+	o := new(outcome)
+	return context.WithValue(ctx, missTrackingKey{}, o), o
+}
+
+// Instrumented wraps whatever setupCache returns, reporting hit/miss/error
+// counters and resolution latency to Prometheus. A "hit" is a Resolve call
+// that explicitly reported being served from cache via markHit; everything
+// else is counted as a miss. setupCache always puts an Expiring (which calls
+// markHit/markMiss) in front of both CacheTypeMemory and CacheTypeRedis, so
+// in practice every configured cache reports real hits; only a Cacher used
+// without Expiring in front of it (not something setupCache builds) would be
+// pinned at 0% hits.
+type Instrumented struct {
+	Backed validation.LicenseResolver
+
+	Hits, Misses, Errors prometheus.Counter
+	Duration             prometheus.Histogram
+}
+
+// NewInstrumented registers the counters on registry and returns an
+// Instrumented wrapping backed.
+func NewInstrumented(registry *prometheus.Registry, backed validation.LicenseResolver) *Instrumented {
+	factory := promauto.With(registry)
+
+	const (
+		namespace = "licensevalidator"
+		subsystem = "cache"
+	)
+
+	return &Instrumented{
+		Backed: backed,
+		Hits: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem, Name: "hits_total",
+			Help: "Number of license resolutions served from cache.",
+		}),
+		Misses: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem, Name: "misses_total",
+			Help: "Number of license resolutions that required a backing lookup.",
+		}),
+		Errors: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem, Name: "errors_total",
+			Help: "Number of license resolutions that ended in an error.",
+		}),
+		Duration: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: subsystem, Name: "resolve_duration_seconds",
+			Help:    "Cache Resolve latency in seconds, including any backing lookup.",
+			Buckets: prometheus.DefBuckets,
+		}),
+	}
+}
+
+// Resolve implements validation.LicenseResolver.
+func (c *Instrumented) Resolve(ctx context.Context, module, version string) (validation.License, error) {
+	start := time.Now()
+	ctx, o := withMissTracking(ctx)
+
+	license, err := c.Backed.Resolve(ctx, module, version)
+
+	c.Duration.Observe(time.Since(start).Seconds())
+
+	switch {
+	case err != nil:
+		c.Errors.Inc()
+	case *o == outcomeHit:
+		c.Hits.Inc()
+	default:
+		c.Misses.Inc()
+	}
+
+	return license, err
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process Store backed by a plain map. Entries past
+// their ExpiresAt are treated as a miss and evicted lazily on the next Get,
+// mirroring the TTL RedisCache gets from Redis itself.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]StoredEntry
+}
+
+// Get implements Store.
+func (m *MemoryStore) Get(_ context.Context, key string) (StoredEntry, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return StoredEntry{}, false, nil
+	}
+
+	if time.Now().After(entry.ExpiresAt) {
+		delete(m.entries, key)
+		return StoredEntry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+// Set implements Store.
+func (m *MemoryStore) Set(_ context.Context, key string, entry StoredEntry, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries == nil {
+		m.entries = make(map[string]StoredEntry)
+	}
+	m.entries[key] = entry
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "licensevalidator:license:"
+
+// RedisCache is a Store backed by Redis, keyed by "<module>@<version>". It
+// keeps a small in-process LRU in front of Redis to absorb hot keys so
+// repeated lookups of the same module/version don't round-trip every time.
+type RedisCache struct {
+	Client *redis.Client
+
+	// LRUSize bounds the in-process LRU placed in front of Redis. Zero
+	// disables it.
+	LRUSize int
+
+	once sync.Once
+	lru  *lru.Cache[string, StoredEntry]
+}
+
+// Get implements Store.
+func (c *RedisCache) Get(ctx context.Context, key string) (StoredEntry, bool, error) {
+	c.init()
+
+	if c.lru != nil {
+		if entry, ok := c.lru.Get(key); ok {
+			if time.Now().Before(entry.ExpiresAt) {
+				return entry, true, nil
+			}
+
+			// Past its expiry: drop it and let Redis (and its TTL) decide.
+			c.lru.Remove(key)
+		}
+	}
+
+	raw, err := c.Client.Get(ctx, redisKeyPrefix+key).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return StoredEntry{}, false, nil
+	case err != nil:
+		return StoredEntry{}, false, fmt.Errorf("redis get failed: %w", err)
+	}
+
+	var entry StoredEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return StoredEntry{}, false, fmt.Errorf("redis entry decode failed: %w", err)
+	}
+
+	if c.lru != nil {
+		c.lru.Add(key, entry)
+	}
+
+	return entry, true, nil
+}
+
+// Set implements Store.
+func (c *RedisCache) Set(ctx context.Context, key string, entry StoredEntry, ttl time.Duration) error {
+	c.init()
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("redis entry encode failed: %w", err)
+	}
+
+	if err := c.Client.Set(ctx, redisKeyPrefix+key, raw, ttl).Err(); err != nil {
+		return fmt.Errorf("redis set failed: %w", err)
+	}
+
+	if c.lru != nil {
+		c.lru.Add(key, entry)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) init() {
+	c.once.Do(func() {
+		if c.LRUSize <= 0 {
+			return
+		}
+
+		// Cache.New only fails for a non-positive size, already excluded above.
+		c.lru, _ = lru.New[string, StoredEntry](c.LRUSize)
+	})
+}
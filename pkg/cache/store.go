@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+)
+
+// StoredEntry is the value persisted by a Store, carrying the resolved
+// license (or the resolution error, so failures can be negatively cached)
+// alongside the point in time it expires at.
+//
+// ErrKind records which well-known sentinel error (if any) Err came from, so
+// a negatively-cached error can be reconstructed with its identity intact
+// for errors.Is; an empty ErrKind with a non-empty Err is an arbitrary error
+// carried only as a message.
+type StoredEntry struct {
+	License   validation.License
+	Err       string
+	ErrKind   string
+	ExpiresAt time.Time
+}
+
+// Store is a minimal TTL-aware key-value store used by Expiring to persist
+// cached license lookups across backends (in-process, Redis, ...).
+type Store interface {
+	Get(ctx context.Context, key string) (entry StoredEntry, ok bool, err error)
+	Set(ctx context.Context, key string, entry StoredEntry, ttl time.Duration) error
+}
@@ -0,0 +1,165 @@
+// Package gitea implements validation.LicenseResolver by fetching license
+// files from the Gitea REST API, for modules hosted on self-hosted Gitea
+// instances.
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/pkg/observ"
+	"github.com/xakep666/licensevalidator/pkg/spdx"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/google/licensecheck"
+	"go.uber.org/zap"
+)
+
+// candidateFiles lists the file names tried, in order, when looking up a
+// module's license in a repository.
+var candidateFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+
+// pseudoVersionCommit recovers the commit hash embedded in a Go pseudo-version
+// such as "v0.0.0-20200101000000-abcdef123456".
+var pseudoVersionCommit = regexp.MustCompile(`-([0-9a-f]{12})$`)
+
+// ClientParams configures a Client.
+type ClientParams struct {
+	HTTPClient *http.Client
+
+	// BaseURL is the root URL of the Gitea instance (the "/api/v1" path is
+	// appended by the client), e.g. "https://gitea.company.tld".
+	BaseURL string
+
+	// AccessToken is sent as an Authorization: token ... header for private repositories.
+	AccessToken string
+
+	// Hosts lists the module path prefixes this client is responsible for.
+	// Modules that don't match any of these are rejected with
+	// validation.ErrUnsupportedModule.
+	Hosts []string
+
+	FallbackConfidenceThreshold float64
+}
+
+// Client resolves licenses for modules hosted on a Gitea instance.
+type Client struct {
+	log *zap.Logger
+
+	httpClient  *http.Client
+	baseURL     string
+	accessToken string
+	hosts       []string
+	threshold   float64
+}
+
+// NewClient constructs a Client from params.
+func NewClient(log *zap.Logger, params ClientParams) *Client {
+	return &Client{
+		log:         log,
+		httpClient:  params.HTTPClient,
+		baseURL:     strings.TrimRight(params.BaseURL, "/"),
+		accessToken: params.AccessToken,
+		hosts:       params.Hosts,
+		threshold:   params.FallbackConfidenceThreshold,
+	}
+}
+
+// Resolve implements validation.LicenseResolver.
+func (c *Client) Resolve(ctx context.Context, module, version string) (validation.License, error) {
+	owner, repo, ok := c.ownerRepo(module)
+	if !ok {
+		return validation.License{}, validation.ErrUnsupportedModule
+	}
+
+	ref := refFromVersion(version)
+
+	for _, file := range candidateFiles {
+		content, err := c.fetchFile(ctx, owner, repo, file, ref)
+		if err != nil {
+			observ.LoggerFromContext(ctx).Debug("gitea license file fetch failed",
+				zap.String("module", module), zap.String("file", file), zap.Error(err))
+			continue
+		}
+
+		cov := licensecheck.Scan(content)
+		for _, m := range cov.Match {
+			if m.Percent/100 < c.threshold {
+				continue
+			}
+
+			name := m.ID
+			if lic, ok := spdx.LicenseByID(m.ID); ok {
+				name = lic.Name
+			}
+
+			return validation.License{SPDXID: m.ID, Name: name}, nil
+		}
+	}
+
+	return validation.License{}, validation.ErrLicenseNotFound
+}
+
+// refFromVersion translates a Go module version into a ref the Gitea API
+// accepts: a pseudo-version resolves to its embedded commit hash and the
+// "+incompatible" suffix (meaningless outside of Go) is stripped; anything
+// else (a tag like "v1.2.3") is used as-is.
+func refFromVersion(version string) string {
+	version = strings.TrimSuffix(version, "+incompatible")
+
+	if m := pseudoVersionCommit.FindStringSubmatch(version); m != nil {
+		return m[1]
+	}
+
+	return version
+}
+
+// ownerRepo strips the configured host prefix from module and splits the
+// remainder into owner and repository name, reporting false if module isn't
+// hosted on any of c.hosts.
+func (c *Client) ownerRepo(module string) (owner, repo string, ok bool) {
+	for _, host := range c.hosts {
+		prefix := host + "/"
+		if !strings.HasPrefix(module, prefix) {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(module, prefix), "/", 3)
+		if len(parts) < 2 {
+			return "", "", false
+		}
+
+		return parts[0], parts[1], true
+	}
+
+	return "", "", false
+}
+
+func (c *Client) fetchFile(ctx context.Context, owner, repo, file, ref string) ([]byte, error) {
+	u := fmt.Sprintf("%s/api/v1/repos/%s/%s/raw/%s?ref=%s", c.baseURL, owner, repo, file, ref)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request build failed: %w", err)
+	}
+
+	if c.accessToken != "" {
+		req.Header.Set("Authorization", "token "+c.accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,182 @@
+// Package gitlab implements validation.LicenseResolver by fetching license
+// files from the GitLab REST API. It supports both gitlab.com and self-hosted
+// instances reachable under a configured base URL.
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/xakep666/licensevalidator/pkg/observ"
+	"github.com/xakep666/licensevalidator/pkg/spdx"
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/google/licensecheck"
+	"go.uber.org/zap"
+)
+
+// candidateFiles lists the file names tried, in order, when looking up a
+// module's license in a repository.
+var candidateFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+
+// pseudoVersionCommit recovers the commit hash embedded in a Go pseudo-version
+// such as "v0.0.0-20200101000000-abcdef123456".
+var pseudoVersionCommit = regexp.MustCompile(`-([0-9a-f]{12})$`)
+
+// goMajorVersionSuffix matches the "/vN" (N >= 2) suffix Go appends to a
+// module path for major version 2 and above; it's not part of the
+// repository's actual path.
+var goMajorVersionSuffix = regexp.MustCompile(`/v([2-9]|[1-9][0-9]+)$`)
+
+// ClientParams configures a Client.
+type ClientParams struct {
+	HTTPClient *http.Client
+
+	// BaseURL is the root URL of the GitLab instance (the "/api/v4" path is
+	// appended by the client), e.g. "https://gitlab.com" or
+	// "https://gitlab.company.tld". Defaults to "https://gitlab.com".
+	BaseURL string
+
+	// AccessToken is sent as a PRIVATE-TOKEN header for private repositories.
+	AccessToken string
+
+	// Hosts lists the module path prefixes this client is responsible for
+	// (e.g. "gitlab.com", "gitlab.company.tld"). Modules that don't match any
+	// of these are rejected with validation.ErrUnsupportedModule. Defaults to
+	// "gitlab.com" only when BaseURL is empty as well.
+	Hosts []string
+
+	FallbackConfidenceThreshold float64
+}
+
+// Client resolves licenses for modules hosted on GitLab or a self-hosted
+// GitLab instance.
+type Client struct {
+	log *zap.Logger
+
+	httpClient  *http.Client
+	baseURL     string
+	accessToken string
+	hosts       []string
+	threshold   float64
+}
+
+// NewClient constructs a Client from params.
+func NewClient(log *zap.Logger, params ClientParams) *Client {
+	baseURL := params.BaseURL
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+
+	hosts := params.Hosts
+	if len(hosts) == 0 && params.BaseURL == "" {
+		hosts = []string{"gitlab.com"}
+	}
+
+	return &Client{
+		log:         log,
+		httpClient:  params.HTTPClient,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		accessToken: params.AccessToken,
+		hosts:       hosts,
+		threshold:   params.FallbackConfidenceThreshold,
+	}
+}
+
+// Resolve implements validation.LicenseResolver.
+func (c *Client) Resolve(ctx context.Context, module, version string) (validation.License, error) {
+	projectPath, ok := c.projectPath(module)
+	if !ok {
+		return validation.License{}, validation.ErrUnsupportedModule
+	}
+
+	ref := refFromVersion(version)
+
+	for _, file := range candidateFiles {
+		content, err := c.fetchFile(ctx, projectPath, file, ref)
+		if err != nil {
+			observ.LoggerFromContext(ctx).Debug("gitlab license file fetch failed",
+				zap.String("module", module), zap.String("file", file), zap.Error(err))
+			continue
+		}
+
+		cov := licensecheck.Scan(content)
+		for _, m := range cov.Match {
+			if m.Percent/100 < c.threshold {
+				continue
+			}
+
+			name := m.ID
+			if lic, ok := spdx.LicenseByID(m.ID); ok {
+				name = lic.Name
+			}
+
+			return validation.License{SPDXID: m.ID, Name: name}, nil
+		}
+	}
+
+	return validation.License{}, validation.ErrLicenseNotFound
+}
+
+// refFromVersion translates a Go module version into a ref the GitLab API
+// accepts: a pseudo-version resolves to its embedded commit hash and the
+// "+incompatible" suffix (meaningless outside of Go) is stripped; anything
+// else (a tag like "v1.2.3") is used as-is.
+func refFromVersion(version string) string {
+	version = strings.TrimSuffix(version, "+incompatible")
+
+	if m := pseudoVersionCommit.FindStringSubmatch(version); m != nil {
+		return m[1]
+	}
+
+	return version
+}
+
+// projectPath strips the configured host prefix and any Go major-version
+// suffix (e.g. the "/v2" in "gitlab.com/foo/bar/v2") from module, returning
+// the repository path usable in the GitLab API. Reports false if module
+// isn't hosted on any of c.hosts.
+func (c *Client) projectPath(module string) (string, bool) {
+	for _, host := range c.hosts {
+		prefix := host + "/"
+		if strings.HasPrefix(module, prefix) {
+			path := strings.TrimSuffix(strings.TrimPrefix(module, prefix), "/")
+			path = goMajorVersionSuffix.ReplaceAllString(path, "")
+
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+func (c *Client) fetchFile(ctx context.Context, projectPath, file, ref string) ([]byte, error) {
+	u := fmt.Sprintf("%s/api/v4/projects/%s/repository/files/%s/raw?ref=%s",
+		c.baseURL, url.PathEscape(projectPath), url.PathEscape(file), url.QueryEscape(ref))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request build failed: %w", err)
+	}
+
+	if c.accessToken != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
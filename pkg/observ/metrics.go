@@ -0,0 +1,86 @@
+package observ
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/xakep666/licensevalidator/pkg/validation"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors shared by the HTTP middleware and
+// the other subsystems (cache, license resolvers) that report against the
+// same registry.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requestDuration *prometheus.HistogramVec
+	responseSize    *prometheus.HistogramVec
+	resolverLatency *prometheus.HistogramVec
+}
+
+// NewMetrics creates a fresh registry and registers the collectors on it.
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+	factory := promauto.With(registry)
+
+	return &Metrics{
+		Registry: registry,
+		requestDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "licensevalidator",
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "path", "status_class"}),
+		responseSize: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "licensevalidator",
+			Subsystem: "http",
+			Name:      "response_size_bytes",
+			Help:      "HTTP response size in bytes.",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 8),
+		}, []string{"method", "path", "status_class"}),
+		resolverLatency: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "licensevalidator",
+			Subsystem: "resolver",
+			Name:      "duration_seconds",
+			Help:      "License resolver latency in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"resolver"}),
+	}
+}
+
+// WrapResolver wraps r so every call is timed and reported under the
+// resolver_duration_seconds histogram labeled with name.
+func (m *Metrics) WrapResolver(name string, r validation.LicenseResolver) validation.LicenseResolver {
+	return &instrumentedResolver{name: name, resolver: r, histogram: m.resolverLatency.WithLabelValues(name)}
+}
+
+type instrumentedResolver struct {
+	name      string
+	resolver  validation.LicenseResolver
+	histogram prometheus.Observer
+}
+
+func (r *instrumentedResolver) Resolve(ctx context.Context, module, version string) (validation.License, error) {
+	start := time.Now()
+	license, err := r.resolver.Resolve(ctx, module, version)
+	r.histogram.Observe(time.Since(start).Seconds())
+
+	return license, err
+}
+
+func statusClass(code int) string {
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// MetricsHandler exposes the registry in the Prometheus exposition format,
+// suitable for mounting on a mux (see NewApp).
+func (m *Metrics) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
@@ -1,14 +1,37 @@
 package observ
 
 import (
+	"context"
 	"io"
 	"net"
 	"net/http"
 	"time"
 
+	"github.com/felixge/httpsnoop"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
+// RequestIDHeader is checked on incoming requests and echoed back on the
+// response; a fresh ID is generated when it's absent.
+const RequestIDHeader = "X-Request-Id"
+
+type loggerCtxKey struct{}
+
+// LoggerFromContext returns the request-scoped logger stashed in ctx by
+// Middleware, pre-tagged with the request's correlation ID, method and path,
+// so pkg/validation, pkg/github and pkg/goproxy can log against the same
+// correlation ID as the request that triggered them. Falls back to zap.L()
+// outside a request handled by Middleware.
+func LoggerFromContext(ctx context.Context) *zap.Logger {
+	if log, ok := ctx.Value(loggerCtxKey{}).(*zap.Logger); ok {
+		return log
+	}
+
+	return zap.L()
+}
+
 type ReadCounter struct {
 	io.ReadCloser
 
@@ -21,58 +44,61 @@ func (r *ReadCounter) Read(b []byte) (int, error) {
 	return n, err
 }
 
-type WriterInterceptor struct {
-	http.ResponseWriter
-
-	Size int
-	code int
-}
-
-func (w *WriterInterceptor) WriteHeader(code int) {
-	w.code = code
-	w.ResponseWriter.WriteHeader(code)
-}
-
-func (w *WriterInterceptor) Write(b []byte) (int, error) {
-	n, err := w.ResponseWriter.Write(b)
-	w.Size += n
-
-	return n, err
-}
-
-func (w *WriterInterceptor) Code() int {
-	if w.code == 0 {
-		return http.StatusOK
-	}
-
-	return w.code
-}
-
-func Middleware(log *zap.Logger) func(next http.Handler) http.Handler {
+// Middleware assigns every request a correlation ID, logs it, and, when m is
+// non-nil, reports its latency and response size to Prometheus. Fast,
+// successful requests are logged at debug; anything slower than
+// slowRequestThreshold (when positive) or that fails with a 4xx/5xx is
+// logged at info so busy deployments aren't flooded.
+func Middleware(log *zap.Logger, m *Metrics, slowRequestThreshold time.Duration) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
 			r.RemoteAddr = ReadUserIP(r)
 			readCounter := ReadCounter{ReadCloser: r.Body}
-			writerInterceptor := WriterInterceptor{ResponseWriter: w}
-
 			r.Body = &readCounter
 
-			next.ServeHTTP(&writerInterceptor, r)
-
-			latency := time.Since(start)
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
 
-			log.Info("HTTP Request",
+			reqLogger := log.With(
+				zap.String("request_id", requestID),
 				zap.String("method", r.Method),
 				zap.Stringer("url", r.URL),
-				zap.Duration("latency", latency),
-				zap.Int("request_size", readCounter.Size),
-				zap.Int("response_size", writerInterceptor.Size),
-				zap.Int("response_code", writerInterceptor.Code()),
-				zap.String("host", r.Host),
-				zap.String("from", r.RemoteAddr),
-				zap.String("user-agent", r.Header.Get("User-Agent")),
 			)
+			r = r.WithContext(context.WithValue(r.Context(), loggerCtxKey{}, reqLogger))
+
+			// httpsnoop.CaptureMetrics wraps w with exactly the combination of
+			// optional interfaces (http.Hijacker, http.Flusher, http.Pusher,
+			// io.ReaderFrom, http.CloseNotifier, ...) it already implements, so
+			// handlers relying on them (SSE, websockets, sendfile) keep working.
+			metrics := httpsnoop.CaptureMetrics(w, func(ww http.ResponseWriter) {
+				next.ServeHTTP(ww, r)
+			})
+
+			level := zapcore.DebugLevel
+			if metrics.Code >= http.StatusBadRequest || (slowRequestThreshold > 0 && metrics.Duration >= slowRequestThreshold) {
+				level = zapcore.InfoLevel
+			}
+
+			if ce := reqLogger.Check(level, "HTTP Request"); ce != nil {
+				ce.Write(
+					zap.Duration("latency", metrics.Duration),
+					zap.Int("request_size", readCounter.Size),
+					zap.Int64("response_size", metrics.Written),
+					zap.Int("response_code", metrics.Code),
+					zap.String("host", r.Host),
+					zap.String("from", r.RemoteAddr),
+					zap.String("user-agent", r.Header.Get("User-Agent")),
+				)
+			}
+
+			if m != nil {
+				class := statusClass(metrics.Code)
+				m.requestDuration.WithLabelValues(r.Method, r.URL.Path, class).Observe(metrics.Duration.Seconds())
+				m.responseSize.WithLabelValues(r.Method, r.URL.Path, class).Observe(float64(metrics.Written))
+			}
 		})
 	}
 }
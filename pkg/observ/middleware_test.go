@@ -0,0 +1,101 @@
+package observ
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// minimalWriter implements only the http.ResponseWriter methods -- none of
+// the optional interfaces httpsnoop.CaptureMetrics knows how to pass
+// through (Hijacker, Flusher, Pusher, ReaderFrom, CloseNotifier).
+type minimalWriter struct {
+	header http.Header
+	code   int
+}
+
+func newMinimalWriter() *minimalWriter { return &minimalWriter{header: make(http.Header)} }
+
+func (w *minimalWriter) Header() http.Header         { return w.header }
+func (w *minimalWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *minimalWriter) WriteHeader(code int)        { w.code = code }
+
+type hijackableWriter struct{ *minimalWriter }
+
+func (hijackableWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) { return nil, nil, nil }
+
+type flushableWriter struct{ *minimalWriter }
+
+func (flushableWriter) Flush() {}
+
+type pushableWriter struct{ *minimalWriter }
+
+func (pushableWriter) Push(target string, opts *http.PushOptions) error { return nil }
+
+type readerFromWriter struct{ *minimalWriter }
+
+func (readerFromWriter) ReadFrom(r io.Reader) (int64, error) { return 0, nil }
+
+type closeNotifierWriter struct{ *minimalWriter }
+
+func (closeNotifierWriter) CloseNotify() <-chan bool { return make(chan bool) }
+
+// TestMiddlewarePassesThroughOptionalInterfaces verifies that, for every
+// optional http.ResponseWriter interface httpsnoop.CaptureMetrics knows
+// about, Middleware's response writer implements it if and only if the
+// underlying writer does.
+func TestMiddlewarePassesThroughOptionalInterfaces(t *testing.T) {
+	cases := []struct {
+		name                                                            string
+		writer                                                          http.ResponseWriter
+		isHijacker, isFlusher, isPusher, isReaderFrom, isCloseNotifier bool
+	}{
+		{name: "plain", writer: newMinimalWriter()},
+		{name: "hijacker", writer: hijackableWriter{newMinimalWriter()}, isHijacker: true},
+		{name: "flusher", writer: flushableWriter{newMinimalWriter()}, isFlusher: true},
+		{name: "pusher", writer: pushableWriter{newMinimalWriter()}, isPusher: true},
+		{name: "readerFrom", writer: readerFromWriter{newMinimalWriter()}, isReaderFrom: true},
+		{name: "closeNotifier", writer: closeNotifierWriter{newMinimalWriter()}, isCloseNotifier: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var seen struct {
+				hijacker, flusher, pusher, readerFrom, closeNotifier bool
+			}
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, seen.hijacker = w.(http.Hijacker)
+				_, seen.flusher = w.(http.Flusher)
+				_, seen.pusher = w.(http.Pusher)
+				_, seen.readerFrom = w.(io.ReaderFrom)
+				_, seen.closeNotifier = w.(http.CloseNotifier)
+
+				w.WriteHeader(http.StatusOK)
+			})
+
+			Middleware(zap.NewNop(), nil, 0)(next).ServeHTTP(tc.writer, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if seen.hijacker != tc.isHijacker {
+				t.Errorf("Hijacker passthrough = %v, want %v", seen.hijacker, tc.isHijacker)
+			}
+			if seen.flusher != tc.isFlusher {
+				t.Errorf("Flusher passthrough = %v, want %v", seen.flusher, tc.isFlusher)
+			}
+			if seen.pusher != tc.isPusher {
+				t.Errorf("Pusher passthrough = %v, want %v", seen.pusher, tc.isPusher)
+			}
+			if seen.readerFrom != tc.isReaderFrom {
+				t.Errorf("ReaderFrom passthrough = %v, want %v", seen.readerFrom, tc.isReaderFrom)
+			}
+			if seen.closeNotifier != tc.isCloseNotifier {
+				t.Errorf("CloseNotifier passthrough = %v, want %v", seen.closeNotifier, tc.isCloseNotifier)
+			}
+		})
+	}
+}
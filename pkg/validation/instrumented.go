@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Instrumented wraps a Validator, counting allow/deny outcomes emitted while
+// serving admission requests. It can't report a separate "unknown" outcome:
+// NotifyingValidator already resolves an unknown license to an allow or a
+// deny via UnknownLicenseAction before Validate returns, so by the time
+// Instrumented sees the result that signal is gone.
+type Instrumented struct {
+	Validator Validator
+
+	Allowed, Denied prometheus.Counter
+}
+
+// NewInstrumented registers the counters on registry and returns an
+// Instrumented wrapping validator.
+func NewInstrumented(registry *prometheus.Registry, validator Validator) *Instrumented {
+	factory := promauto.With(registry)
+
+	newCounter := func(outcome string) prometheus.Counter {
+		return factory.NewCounter(prometheus.CounterOpts{
+			Namespace:   "licensevalidator",
+			Subsystem:   "admission",
+			Name:        "outcomes_total",
+			Help:        "Number of admission decisions, by outcome.",
+			ConstLabels: prometheus.Labels{"outcome": outcome},
+		})
+	}
+
+	return &Instrumented{
+		Validator: validator,
+		Allowed:   newCounter("allow"),
+		Denied:    newCounter("deny"),
+	}
+}
+
+// Validate implements Validator.
+func (v *Instrumented) Validate(ctx context.Context, module, version string) error {
+	err := v.Validator.Validate(ctx, module, version)
+
+	if err != nil {
+		v.Denied.Inc()
+	} else {
+		v.Allowed.Inc()
+	}
+
+	return err
+}